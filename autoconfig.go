@@ -1,41 +1,66 @@
-// Package autoconfig wraps a JSON or YAML configuration stored on disk that is queryable using the Get* functions.
+// Package autoconfig wraps a configuration stored on disk that is queryable using the Get* functions.
+// JSON, YAML, TOML, HCL, Java-properties, and dotenv files are all supported out of the box, selected by
+// file extension or, failing that, by sniffing the content; see RegisterDecoder to add further formats.
 //
 // The configuration file will be watched for changes after the initial load. Whenever the file has changed, each
-// validation function will be called in the order they were added.
+// validation function will be called in the order they were added, followed by any callbacks registered with
+// OnChange. Callers that want a typed view of the configuration instead of individual Get* calls can use
+// Unmarshal or UnmarshalKey.
 package autoconfig
 
 import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/clbanning/mxj"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/afero"
-	"gopkg.in/yaml.v2"
 )
 
 var Fs = afero.NewOsFs()
 
+// defaultEnvListSeparator is used to split an environment variable's value into a list of
+// strings for GetAll, unless overridden with SetEnvListSeparator.
+const defaultEnvListSeparator = ","
+
 // Config wraps a JSON/YAML configuration stored on disk and provides functions to query it.
 type Config struct {
 	sync.RWMutex
-	filename   string
-	mv         mxj.Map
-	defaults   mxj.Map
-	validators []func(old *Config, new *Config) error
-	loaded     bool
+	filename         string
+	mv               mxj.Map
+	defaults         mxj.Map
+	validators       []func(old *Config, new *Config) error
+	loaded           bool
+	envBindings      map[string][]string
+	envPrefix        string
+	automaticEnv     bool
+	envListSeparator string
+	overrideSuffix   string
+	overlays         []string
+	remoteProviders  []RemoteProvider
+	onChange         []func(c *Config)
 }
 
+// defaultOverrideSuffix is appended to filename to find an adjacent override file, e.g. "test.config"
+// becomes "test.config.local". Change it with SetOverrideSuffix, or set it to "" to disable.
+const defaultOverrideSuffix = ".local"
+
 // New creates a new empty configuration.
 func New(filename string) *Config {
 	return &Config{
-		filename: filename,
-		mv:       mxj.Map{},
-		defaults: mxj.Map{},
+		filename:         filename,
+		mv:               mxj.Map{},
+		defaults:         mxj.Map{},
+		envBindings:      map[string][]string{},
+		envListSeparator: defaultEnvListSeparator,
+		overrideSuffix:   defaultOverrideSuffix,
 	}
 }
 
@@ -71,11 +96,95 @@ func (c *Config) AddValidator(f func(old, new *Config) error) {
 	c.Unlock()
 }
 
+// OnChange registers a callback that is called after every successful configuration load or reload, once
+// validators have passed and the new configuration is in place. Unlike a validator, it cannot reject the
+// new configuration; it's meant for long-running callers that want to re-derive a typed config (see
+// Unmarshal) atomically whenever the file or a remote provider is reloaded. Watch only watches the main
+// config file, so a change to an overlay alone does not trigger a reload.
+func (c *Config) OnChange(f func(c *Config)) {
+	c.Lock()
+	c.onChange = append(c.onChange, f)
+	c.Unlock()
+}
+
+// fireOnChange calls every OnChange callback with c. It must not be called while c's lock is held.
+func (c *Config) fireOnChange() {
+	c.RLock()
+	callbacks := append([]func(c *Config){}, c.onChange...)
+	c.RUnlock()
+	for _, f := range callbacks {
+		f(c)
+	}
+}
+
+// BindEnv binds a dotted configuration path to one or more environment variable names.
+// The environment variables are checked in the order given and the first one with a non-empty value wins.
+// A bound environment variable takes priority over the value in the configuration file, so operators can
+// override file values without editing the file.
+func (c *Config) BindEnv(key string, envVars ...string) {
+	c.Lock()
+	defer c.Unlock()
+	c.envBindings[key] = envVars
+}
+
+// SetEnvPrefix sets a prefix that AutomaticEnv prepends to derived environment variable names.
+func (c *Config) SetEnvPrefix(prefix string) {
+	c.Lock()
+	defer c.Unlock()
+	c.envPrefix = prefix
+}
+
+// AutomaticEnv enables automatic derivation of an environment variable name from each config path that is
+// looked up, in addition to any names registered with BindEnv. The path is uppercased and dots are replaced
+// with underscores, e.g. "spanner.database.path" becomes SPANNER_DATABASE_PATH. If SetEnvPrefix has been
+// called, the prefix (also uppercased, followed by an underscore) is prepended.
+func (c *Config) AutomaticEnv() {
+	c.Lock()
+	defer c.Unlock()
+	c.automaticEnv = true
+}
+
+// SetEnvListSeparator sets the separator used to split an environment variable's value into a list of
+// strings for GetAll. The default separator is a comma.
+func (c *Config) SetEnvListSeparator(sep string) {
+	c.Lock()
+	defer c.Unlock()
+	c.envListSeparator = sep
+}
+
+// envVarName returns the environment variable name that AutomaticEnv derives for path.
+func (c *Config) envVarName(path string) string {
+	name := strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+	if c.envPrefix == "" {
+		return name
+	}
+	return strings.ToUpper(c.envPrefix) + "_" + name
+}
+
+// envValue returns the value bound to path via BindEnv or AutomaticEnv, checked in that order, and whether
+// a non-empty value was found. It must be called with c's lock already held.
+func (c *Config) envValue(path string) (string, bool) {
+	for _, envVar := range c.envBindings[path] {
+		if v := os.Getenv(envVar); v != "" {
+			return v, true
+		}
+	}
+	if c.automaticEnv {
+		if v := os.Getenv(c.envVarName(path)); v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
 // GetRaw looks up the raw configuration item and does not do any conversion to a particular type.
 // This is generally only used by the other Get* functions but is exposed for convenience.
 func (c *Config) GetRaw(path string) interface{} {
 	c.RLock()
 	defer c.RUnlock()
+	if v, ok := c.envValue(path); ok {
+		return v
+	}
 	values, err := c.mv.ValuesForPath(path)
 	if err != nil {
 		log.Printf("Error in ValuesForPath(%q): %v", path, err)
@@ -122,6 +231,13 @@ func (c *Config) GetFloat(path string) float64 {
 	switch t := i.(type) {
 	case float64:
 		return t
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			log.Printf("GetFloat() Error in value %q, expected float64, got %q", path, t)
+			return 0
+		}
+		return f
 	default:
 		log.Printf("GetFloat() Error in value %q, expected float64, got %T", path, t)
 		return 0
@@ -137,8 +253,17 @@ func (c *Config) GetInt(path string) int {
 	switch t := i.(type) {
 	case int:
 		return t
+	case int64:
+		return int(t)
 	case float64:
 		return int(t)
+	case string:
+		n, err := strconv.Atoi(t)
+		if err != nil {
+			log.Printf("GetInt() Error in value %q, expected int, got %q", path, t)
+			return 0
+		}
+		return n
 	default:
 		log.Printf("GetInt() Error in value %q, expected int, got %T", path, t)
 		return 0
@@ -149,6 +274,9 @@ func (c *Config) GetInt(path string) int {
 func (c *Config) GetAll(path string) []string {
 	c.RLock()
 	defer c.RUnlock()
+	if v, ok := c.envValue(path); ok {
+		return strings.Split(v, c.envListSeparator)
+	}
 	values, err := c.mv.ValuesForPath(path)
 	if err != nil {
 		log.Printf("Error in ValuesForPath(%q): %v", path, err)
@@ -196,34 +324,73 @@ func (c *Config) GetMapList(path string) []map[string]interface{} {
 
 	r := make([]map[string]interface{}, 0, len(values))
 	for _, v := range values {
-		m := make(map[string]interface{})
-		for key, value := range v.(map[interface{}]interface{}) {
-			m[key.(string)] = value
+		m, ok := toStringMap(v)
+		if !ok {
+			log.Printf("GetMapList() Error in value %q, expected map, got %T", path, v)
+			continue
 		}
 		r = append(r, m)
 	}
 	return r
 }
 
+// clone returns a new Config that shares c's settings (defaults, env bindings, overlays, remote
+// providers, validators) but not its loaded configuration tree (mv is left nil). It's used to build the
+// candidate configuration that validators are run against before it replaces c's tree.
+func (c *Config) clone() *Config {
+	envBindings := make(map[string][]string, len(c.envBindings))
+	for k, v := range c.envBindings {
+		envBindings[k] = v
+	}
+	return &Config{
+		filename:         c.filename,
+		defaults:         c.defaults,
+		validators:       c.validators,
+		envBindings:      envBindings,
+		envPrefix:        c.envPrefix,
+		automaticEnv:     c.automaticEnv,
+		envListSeparator: c.envListSeparator,
+		overrideSuffix:   c.overrideSuffix,
+		overlays:         c.overlays,
+		remoteProviders:  c.remoteProviders,
+		onChange:         c.onChange,
+	}
+}
+
 func (c *Config) read() error {
 	body, err := afero.ReadFile(Fs, c.filename)
 	if err != nil {
 		return fmt.Errorf("couldn't read config file %q: %v", c.filename, err)
 	}
 
-	mv, err := mxj.NewMapJson(body)
+	m, err := decodeConfig(c.filename, body)
 	if err != nil {
-		mv, err = c.readYAML(body)
-		if err != nil {
-			return fmt.Errorf("couldn't parse config: %v", err)
-		}
+		return fmt.Errorf("couldn't parse config: %v", err)
 	}
+	mv := mxj.Map(m)
+
+	c.RLock()
+	overrideSuffix := c.overrideSuffix
+	overlays := append([]string{}, c.overlays...)
+	c.RUnlock()
 
-	newConfig := &Config{
-		filename: c.filename,
-		mv:       mv,
+	if overrideSuffix != "" {
+		if mv, err = c.mergeOverlayFile(mv, c.filename+overrideSuffix); err != nil {
+			return err
+		}
+	}
+	for _, path := range overlays {
+		if mv, err = c.mergeOverlayFile(mv, path); err != nil {
+			return err
+		}
 	}
-	for _, f := range c.validators {
+
+	c.RLock()
+	newConfig := c.clone()
+	validators := append([]func(old, new *Config) error{}, c.validators...)
+	c.RUnlock()
+	newConfig.mv = mv
+	for _, f := range validators {
 		if err := f(c, newConfig); err != nil {
 			log.Printf("Config validation failed: %v", err)
 			return err
@@ -234,41 +401,47 @@ func (c *Config) read() error {
 	c.mv = mv
 	c.loaded = true
 	c.Unlock()
+	c.fireOnChange()
 	return nil
 }
 
-func (c *Config) readYAML(body []byte) (mxj.Map, error) {
-	mv := mxj.Map{}
-	if err := yaml.Unmarshal(body, &mv); err != nil {
-		return nil, err
-	}
-
-	// This is nasty. yaml.Unmarshal returns maps as map[interface{}]interface{},
-	// where mxj expects them to be map[string]interface{} and won't find nested
-	// values unless it's the correct type. This horrible code converts the
-	// former to the latter.
-	//
-	// TODO(dparrish): Get rid of it.
-	for k, v := range mv {
-		switch t := v.(type) {
-		case map[interface{}]interface{}:
-			mv[k] = convertInterfaceToString(t)
+// mergeOverlayFile reads path, if it exists, and deep-merges it on top of mv. A missing overlay file is
+// not an error; it's simply skipped. The overlay is decoded using the decoder resolved for c.filename,
+// not path, since an overlay's own suffix (e.g. ".local") is never itself a registered format and formats
+// like properties/dotenv that are excluded from content-sniffing would otherwise fail to decode.
+func (c *Config) mergeOverlayFile(mv mxj.Map, path string) (mxj.Map, error) {
+	body, err := afero.ReadFile(Fs, path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return mv, nil
 		}
+		return nil, fmt.Errorf("couldn't read config overlay %q: %v", path, err)
 	}
-
-	return mv, nil
+	overlay, err := decodeConfig(c.filename, body)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse config overlay %q: %v", path, err)
+	}
+	return mxj.Map(mergeMaps(mv, overlay)), nil
 }
 
-func convertInterfaceToString(mv map[interface{}]interface{}) map[string]interface{} {
-	r := map[string]interface{}{}
-	for k, v := range mv {
-		r[k.(string)] = v
-		switch t := v.(type) {
-		case map[interface{}]interface{}:
-			r[k.(string)] = convertInterfaceToString(t)
+// mergeMaps deep-merges overlay on top of base, returning a new map. Where both base and overlay hold a
+// map at the same key, the maps are merged key by key; otherwise the overlay value (including lists and
+// scalars) replaces the base value.
+func mergeMaps(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		if overlayMap, ok := v.(map[string]interface{}); ok {
+			if baseMap, ok := merged[k].(map[string]interface{}); ok {
+				merged[k] = mergeMaps(baseMap, overlayMap)
+				continue
+			}
 		}
+		merged[k] = v
 	}
-	return r
+	return merged
 }
 
 func (c *Config) background(ctx context.Context, watcher *fsnotify.Watcher) {
@@ -329,3 +502,23 @@ func (c *Config) Immutable(key string) {
 func (c *Config) Default(key string, value interface{}) {
 	c.defaults.SetValueForPath(value, key)
 }
+
+// SetOverrideSuffix sets the suffix used to find an adjacent override file next to the config file, e.g.
+// the default ".local" turns "test.config" into "test.config.local". The override file, if present, is
+// deep-merged on top of the base config every time it is read, before overlays added with AddOverlay and
+// before validators run. Set to "" to disable automatic override file merging.
+func (c *Config) SetOverrideSuffix(suffix string) {
+	c.Lock()
+	defer c.Unlock()
+	c.overrideSuffix = suffix
+}
+
+// AddOverlay adds an additional configuration file that is deep-merged on top of the base config (and the
+// override file, if any) every time the config is read. Overlays are merged in the order they were added,
+// so a later overlay takes precedence over an earlier one. Maps are merged key by key; scalars and lists
+// are replaced. A missing overlay file is not an error; it's simply skipped.
+func (c *Config) AddOverlay(path string) {
+	c.Lock()
+	defer c.Unlock()
+	c.overlays = append(c.overlays, path)
+}
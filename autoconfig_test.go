@@ -2,9 +2,12 @@ package autoconfig
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
@@ -148,6 +151,25 @@ func TestGetAllYAML(t *testing.T) {
 	assert.Equal(t, []string{"foo", "bar"}, c.GetAll("hash1.hash2.hash2var1"))
 }
 
+func TestGetMapListJSON(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(`{"hashlist": [{"a": 1}, {"a": 2}]}`), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+	assert.Equal(t, []map[string]interface{}{{"a": 1.0}, {"a": 2.0}}, c.GetMapList("hashlist"))
+}
+
+func TestGetMapListYAML(t *testing.T) {
+	c := loadYAMLConfig()
+	require.NotNil(t, c)
+	assert.Equal(t, []map[string]interface{}{{"key": "value"}, {"key": "value2"}}, c.GetMapList("hash1.hash2.hashlist"))
+}
+
+func TestGetMapListMissingPath(t *testing.T) {
+	c := loadJSONConfig()
+	require.NotNil(t, c)
+	assert.Equal(t, []map[string]interface{}{}, c.GetMapList("nosuchkey"))
+}
+
 func TestValidator(t *testing.T) {
 	c := loadJSONConfig()
 	require.NotNil(t, c)
@@ -205,3 +227,162 @@ func TestRequiredOnUpdate(t *testing.T) {
 	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[1]), 0644)
 	assert.NotNil(t, c.read())
 }
+
+func TestBindEnv(t *testing.T) {
+	c := loadJSONConfig()
+	require.NotNil(t, c)
+	c.BindEnv("var1", "TEST_BINDENV_VAR1")
+
+	// No environment variable set, the file value should still win.
+	assert.Equal(t, "value1", c.Get("var1"))
+
+	os.Setenv("TEST_BINDENV_VAR1", "fromenv")
+	defer os.Unsetenv("TEST_BINDENV_VAR1")
+	assert.Equal(t, "fromenv", c.Get("var1"))
+}
+
+func TestBindEnvFirstNonEmptyWins(t *testing.T) {
+	c := loadJSONConfig()
+	require.NotNil(t, c)
+	c.BindEnv("var1", "TEST_BINDENV_FIRST", "TEST_BINDENV_SECOND")
+
+	os.Setenv("TEST_BINDENV_SECOND", "second")
+	defer os.Unsetenv("TEST_BINDENV_SECOND")
+	assert.Equal(t, "second", c.Get("var1"))
+
+	os.Setenv("TEST_BINDENV_FIRST", "first")
+	defer os.Unsetenv("TEST_BINDENV_FIRST")
+	assert.Equal(t, "first", c.Get("var1"))
+}
+
+func TestAutomaticEnv(t *testing.T) {
+	c := loadJSONConfig()
+	require.NotNil(t, c)
+	c.SetEnvPrefix("myapp")
+	c.AutomaticEnv()
+
+	os.Setenv("MYAPP_HASH1_HASH1VAR1", "fromenv")
+	defer os.Unsetenv("MYAPP_HASH1_HASH1VAR1")
+	assert.Equal(t, "fromenv", c.Get("hash1.hash1var1"))
+}
+
+func TestEnvGetIntAndFloat(t *testing.T) {
+	c := loadJSONConfig()
+	require.NotNil(t, c)
+	c.BindEnv("hash1.intval1", "TEST_BINDENV_INTVAL1")
+
+	os.Setenv("TEST_BINDENV_INTVAL1", "42")
+	defer os.Unsetenv("TEST_BINDENV_INTVAL1")
+	assert.Equal(t, 42, c.GetInt("hash1.intval1"))
+	assert.Equal(t, 42.0, c.GetFloat("hash1.intval1"))
+}
+
+func TestEnvGetAllSeparator(t *testing.T) {
+	c := loadJSONConfig()
+	require.NotNil(t, c)
+	c.BindEnv("hash1.hash2.hash2var1", "TEST_BINDENV_LIST")
+	c.SetEnvListSeparator(";")
+
+	os.Setenv("TEST_BINDENV_LIST", "one;two;three")
+	defer os.Unsetenv("TEST_BINDENV_LIST")
+	assert.Equal(t, []string{"one", "two", "three"}, c.GetAll("hash1.hash2.hash2var1"))
+}
+
+func TestLocalOverrideFile(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	afero.WriteFile(Fs, "test.config.local", []byte(`{"hash1": {"intval1": 99}}`), 0644)
+	defer Fs.Remove("test.config.local")
+	c := New("test.config")
+	require.Nil(t, c.Load())
+
+	// The override file replaces intval1 ...
+	assert.Equal(t, 99, c.GetInt("hash1.intval1"))
+	// ... but leaves the rest of hash1 untouched, since maps are deep-merged.
+	assert.Equal(t, "blah", c.Get("hash1.hash1var1"))
+	assert.Equal(t, "value1", c.Get("var1"))
+}
+
+func TestLocalOverrideFileMissingIsNotAnError(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+	assert.Nil(t, c.Load())
+	assert.Equal(t, "value1", c.Get("var1"))
+}
+
+func TestSetOverrideSuffix(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	afero.WriteFile(Fs, "test.config.override", []byte(`{"var1": "fromoverride"}`), 0644)
+	defer Fs.Remove("test.config.override")
+	c := New("test.config")
+	c.SetOverrideSuffix(".override")
+	require.Nil(t, c.Load())
+	assert.Equal(t, "fromoverride", c.Get("var1"))
+}
+
+func TestAddOverlay(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	afero.WriteFile(Fs, "test.config.local", []byte(`{"var1": "fromlocal"}`), 0644)
+	afero.WriteFile(Fs, "test.overlay", []byte(`{"var1": "fromoverlay"}`), 0644)
+	defer Fs.Remove("test.config.local")
+	defer Fs.Remove("test.overlay")
+	c := New("test.config")
+	c.AddOverlay("test.overlay")
+	require.Nil(t, c.Load())
+
+	// Explicit overlays win over both the base config and the implicit .local override.
+	assert.Equal(t, "fromoverlay", c.Get("var1"))
+}
+
+func TestEnvBindingSurvivesReload(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+	c.BindEnv("var1", "TEST_BINDENV_RELOAD")
+	require.Nil(t, c.Load())
+
+	os.Setenv("TEST_BINDENV_RELOAD", "fromenv")
+	defer os.Unsetenv("TEST_BINDENV_RELOAD")
+
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[1]), 0644)
+	assert.Nil(t, c.read())
+	assert.Equal(t, "fromenv", c.Get("var1"))
+}
+
+// TestConcurrentBindEnvAndRead exercises read()'s clone() of c.envBindings/c.validators concurrently with
+// BindEnv/AddValidator, which mutate those same fields under c's lock. Run with -race: before read() took
+// c.RLock() around the clone, this triggered a data race.
+func TestConcurrentBindEnvAndRead(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.BindEnv("var1", fmt.Sprintf("TEST_CONCURRENT_BINDENV_%d", i))
+			c.AddValidator(func(old, new *Config) error { return nil })
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			assert.Nil(t, c.read())
+		}
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
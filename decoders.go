@@ -0,0 +1,212 @@
+package autoconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/hcl"
+	"github.com/joho/godotenv"
+	"github.com/magiconair/properties"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// Decoder turns the raw bytes of a config file into a tree of nested maps, the common format that the
+// rest of the package (and mxj) operates on. Map keys must be strings all the way down; Decoder
+// implementations are responsible for converting any format-specific map types (such as YAML's
+// map[interface{}]interface{}) before returning.
+type Decoder func(body []byte) (map[string]interface{}, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]Decoder{}
+
+	// sniffOrder lists the extensions tried, in order, when a file's extension doesn't match a registered
+	// decoder. Only unambiguous, structurally distinct formats are sniffed; properties and dotenv accept
+	// almost any text as a (possibly empty) set of key/value pairs, so they're only selected by extension.
+	sniffOrder = []string{".json", ".yaml", ".toml", ".hcl"}
+)
+
+func init() {
+	RegisterDecoder(".json", decodeJSON)
+	RegisterDecoder(".yaml", decodeYAML)
+	RegisterDecoder(".yml", decodeYAML)
+	RegisterDecoder(".toml", decodeTOML)
+	RegisterDecoder(".hcl", decodeHCL)
+	RegisterDecoder(".properties", decodeProperties)
+	RegisterDecoder(".env", decodeDotenv)
+}
+
+// RegisterDecoder registers a decoder function for a file extension, including the leading dot (e.g.
+// ".json"). It overrides any existing decoder for that extension, so callers can add support for
+// additional formats such as CUE or JSON5, or replace a built-in decoder with their own.
+func RegisterDecoder(ext string, fn Decoder) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[ext] = fn
+}
+
+// decodeConfig picks a decoder for path by its extension and uses it to decode body. If no decoder is
+// registered for the extension (including when path has none), each decoder in sniffOrder is tried in
+// turn and the first to succeed wins.
+func decodeConfig(path string, body []byte) (map[string]interface{}, error) {
+	ext := filepath.Ext(path)
+
+	decodersMu.RLock()
+	fn, ok := decoders[ext]
+	decodersMu.RUnlock()
+	if ok {
+		return fn(body)
+	}
+
+	for _, sniffExt := range sniffOrder {
+		decodersMu.RLock()
+		fn, ok := decoders[sniffExt]
+		decodersMu.RUnlock()
+		if !ok {
+			continue
+		}
+		if m, err := fn(body); err == nil {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no registered decoder could parse %q", path)
+}
+
+func decodeJSON(body []byte) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func decodeYAML(body []byte) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+	return normalizeYAML(m).(map[string]interface{}), nil
+}
+
+// normalizeYAML recursively converts the map[interface{}]interface{} values that yaml.Unmarshal produces
+// for nested maps into map[string]interface{}, which is what mxj requires to find nested values.
+func normalizeYAML(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, vv := range t {
+			m[fmt.Sprintf("%v", k)] = normalizeYAML(vv)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, vv := range t {
+			m[k] = normalizeYAML(vv)
+		}
+		return m
+	case []interface{}:
+		r := make([]interface{}, len(t))
+		for i, vv := range t {
+			r[i] = normalizeYAML(vv)
+		}
+		return r
+	default:
+		return v
+	}
+}
+
+func decodeTOML(body []byte) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if err := toml.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func decodeHCL(body []byte) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if err := hcl.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+	return normalizeHCL(m).(map[string]interface{}), nil
+}
+
+// normalizeHCL recursively collapses the []map[string]interface{} that hcl.Unmarshal produces for each
+// block into a plain map[string]interface{}, the same shape JSON/YAML produce for a nested object. A
+// block repeated more than once is kept as a list, since that's how HCL represents repeated blocks.
+func normalizeHCL(v interface{}) interface{} {
+	switch t := v.(type) {
+	case []map[string]interface{}:
+		if len(t) == 1 {
+			return normalizeHCL(t[0])
+		}
+		r := make([]interface{}, len(t))
+		for i, vv := range t {
+			r[i] = normalizeHCL(vv)
+		}
+		return r
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, vv := range t {
+			m[k] = normalizeHCL(vv)
+		}
+		return m
+	case []interface{}:
+		r := make([]interface{}, len(t))
+		for i, vv := range t {
+			r[i] = normalizeHCL(vv)
+		}
+		return r
+	default:
+		return v
+	}
+}
+
+// decodeProperties decodes a Java-style .properties file. Keys are split on "." to build a nested tree,
+// e.g. "spanner.database.path=x" becomes the same tree as the equivalent nested JSON or YAML document.
+func decodeProperties(body []byte) (map[string]interface{}, error) {
+	p, err := properties.LoadString(string(body))
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	for _, key := range p.Keys() {
+		value, _ := p.Get(key)
+		setDottedPath(m, key, value)
+	}
+	return m, nil
+}
+
+// decodeDotenv decodes a dotenv (KEY=value per line) file. Keys are split on "." to build a nested tree,
+// the same as decodeProperties.
+func decodeDotenv(body []byte) (map[string]interface{}, error) {
+	vars, err := godotenv.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	for key, value := range vars {
+		setDottedPath(m, key, value)
+	}
+	return m, nil
+}
+
+// setDottedPath sets value at a dotted path within m, creating intermediate maps as needed.
+func setDottedPath(m map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	cur := m
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := cur[part].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			cur[part] = next
+		}
+		cur = next
+	}
+	cur[parts[len(parts)-1]] = value
+}
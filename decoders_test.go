@@ -0,0 +1,104 @@
+package autoconfig
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validTOMLConfig = `
+var1 = "value1"
+
+[hash1]
+hash1var1 = "blah"
+intval1 = 15
+`
+
+const validHCLConfig = `
+var1 = "value1"
+hash1 {
+  hash1var1 = "blah"
+  intval1 = 15
+}
+`
+
+const validPropertiesConfig = `
+var1=value1
+hash1.hash1var1=blah
+hash1.intval1=15
+`
+
+const validDotenvConfig = `
+VAR1=value1
+`
+
+func TestLoadTOML(t *testing.T) {
+	afero.WriteFile(Fs, "test.config.toml", []byte(validTOMLConfig), 0644)
+	defer Fs.Remove("test.config.toml")
+	c := New("test.config.toml")
+	require.Nil(t, c.Load())
+	assert.Equal(t, "value1", c.Get("var1"))
+	assert.Equal(t, "blah", c.Get("hash1.hash1var1"))
+	assert.Equal(t, 15, c.GetInt("hash1.intval1"))
+}
+
+func TestLoadHCL(t *testing.T) {
+	afero.WriteFile(Fs, "test.config.hcl", []byte(validHCLConfig), 0644)
+	defer Fs.Remove("test.config.hcl")
+	c := New("test.config.hcl")
+	require.Nil(t, c.Load())
+	assert.Equal(t, "value1", c.Get("var1"))
+	assert.Equal(t, "blah", c.Get("hash1.hash1var1"))
+}
+
+func TestLoadProperties(t *testing.T) {
+	afero.WriteFile(Fs, "test.config.properties", []byte(validPropertiesConfig), 0644)
+	defer Fs.Remove("test.config.properties")
+	c := New("test.config.properties")
+	require.Nil(t, c.Load())
+	assert.Equal(t, "value1", c.Get("var1"))
+	assert.Equal(t, "blah", c.Get("hash1.hash1var1"))
+	assert.Equal(t, 15, c.GetInt("hash1.intval1"))
+}
+
+func TestLoadDotenv(t *testing.T) {
+	afero.WriteFile(Fs, "test.config.env", []byte(validDotenvConfig), 0644)
+	defer Fs.Remove("test.config.env")
+	c := New("test.config.env")
+	require.Nil(t, c.Load())
+	assert.Equal(t, "value1", c.Get("VAR1"))
+}
+
+func TestLocalOverrideFileWithDotenvBase(t *testing.T) {
+	afero.WriteFile(Fs, "test.config.env", []byte("VAR1=value1\n"), 0644)
+	afero.WriteFile(Fs, "test.config.env.local", []byte("VAR1=fromlocal\n"), 0644)
+	defer Fs.Remove("test.config.env")
+	defer Fs.Remove("test.config.env.local")
+	c := New("test.config.env")
+	require.Nil(t, c.Load())
+	assert.Equal(t, "fromlocal", c.Get("VAR1"))
+}
+
+func TestLocalOverrideFileWithPropertiesBase(t *testing.T) {
+	afero.WriteFile(Fs, "test.config.properties", []byte(validPropertiesConfig), 0644)
+	afero.WriteFile(Fs, "test.config.properties.local", []byte("hash1.intval1=99\n"), 0644)
+	defer Fs.Remove("test.config.properties")
+	defer Fs.Remove("test.config.properties.local")
+	c := New("test.config.properties")
+	require.Nil(t, c.Load())
+	assert.Equal(t, 99, c.GetInt("hash1.intval1"))
+	assert.Equal(t, "blah", c.Get("hash1.hash1var1"))
+}
+
+func TestRegisterDecoderCustomExtension(t *testing.T) {
+	afero.WriteFile(Fs, "test.config.custom", []byte("irrelevant"), 0644)
+	defer Fs.Remove("test.config.custom")
+	RegisterDecoder(".custom", func(body []byte) (map[string]interface{}, error) {
+		return map[string]interface{}{"var1": "fromcustom"}, nil
+	})
+	c := New("test.config.custom")
+	require.Nil(t, c.Load())
+	assert.Equal(t, "fromcustom", c.Get("var1"))
+}
@@ -0,0 +1,182 @@
+package autoconfig
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"log"
+
+	"github.com/clbanning/mxj"
+	"github.com/spf13/afero"
+)
+
+// RemoteProvider describes a single remote configuration source registered with AddRemoteProvider.
+type RemoteProvider struct {
+	// Provider is the name a backend was registered under with RegisterProvider, e.g. "etcd" or "consul".
+	Provider string
+	// Endpoint is the backend's address, e.g. "http://127.0.0.1:2379" for etcd.
+	Endpoint string
+	// Path is the key (etcd) or path (Consul, Firestore) the configuration is stored under.
+	Path string
+	// SecretKeyring, if set, is the path to a file holding a raw AES key used to decrypt the payload
+	// (AES-GCM, nonce prepended to the ciphertext) before it's handed to the format decoder.
+	SecretKeyring string
+}
+
+// Provider is implemented by a remote configuration backend and registered with RegisterProvider so that
+// Config.AddRemoteProvider can find it by name. See the github.com/dparrish/go-autoconfig/remote
+// subpackage for etcd, Consul, and Firestore implementations; it's a separate module so that the core
+// package doesn't have to depend on any particular backend's client libraries.
+type Provider interface {
+	// Get fetches the current payload for rp.
+	Get(rp RemoteProvider) ([]byte, error)
+	// Watch returns a channel that receives a new payload for rp whenever it changes. The returned
+	// channel, and any goroutine feeding it, must stop when ctx is done; WatchRemoteConfig relies on this
+	// to avoid leaking a goroutine per provider every time it's called.
+	Watch(ctx context.Context, rp RemoteProvider) (<-chan []byte, error)
+}
+
+var providers = map[string]Provider{}
+
+// RegisterProvider registers a remote configuration backend under name, e.g. "etcd". Backend packages
+// call this from an init function.
+func RegisterProvider(name string, p Provider) {
+	providers[name] = p
+}
+
+// AddRemoteProvider registers a remote configuration source that ReadRemoteConfig and WatchRemoteConfig
+// read from. provider must already be registered with RegisterProvider. Multiple remote providers, and a
+// local file, can all be combined; each is deep-merged on top of the previous one, in the order added.
+func (c *Config) AddRemoteProvider(provider, endpoint, path string) error {
+	return c.AddRemoteProviderWithKeyring(provider, endpoint, path, "")
+}
+
+// AddRemoteProviderWithKeyring is like AddRemoteProvider, but the payload read from this provider is
+// decrypted (AES-GCM, using the key stored in keyringPath) before it's handed to the format decoder.
+func (c *Config) AddRemoteProviderWithKeyring(provider, endpoint, path, keyringPath string) error {
+	if _, ok := providers[provider]; !ok {
+		return fmt.Errorf("no remote config provider registered for %q", provider)
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.remoteProviders = append(c.remoteProviders, RemoteProvider{
+		Provider:      provider,
+		Endpoint:      endpoint,
+		Path:          path,
+		SecretKeyring: keyringPath,
+	})
+	return nil
+}
+
+// ReadRemoteConfig reads every provider added with AddRemoteProvider, in order, deep-merging each on top
+// of the current configuration exactly like an overlay file, then runs the usual validator pipeline
+// against the merged result.
+func (c *Config) ReadRemoteConfig() error {
+	c.RLock()
+	rps := append([]RemoteProvider{}, c.remoteProviders...)
+	mv := c.mv
+	c.RUnlock()
+
+	var err error
+	for _, rp := range rps {
+		if mv, err = c.mergeRemoteProvider(mv, rp); err != nil {
+			return err
+		}
+	}
+
+	c.RLock()
+	newConfig := c.clone()
+	validators := append([]func(old, new *Config) error{}, c.validators...)
+	c.RUnlock()
+	newConfig.mv = mv
+	for _, f := range validators {
+		if err := f(c, newConfig); err != nil {
+			log.Printf("Config validation failed: %v", err)
+			return err
+		}
+	}
+
+	c.Lock()
+	c.mv = mv
+	c.loaded = true
+	c.Unlock()
+	c.fireOnChange()
+	return nil
+}
+
+// WatchRemoteConfig starts a background goroutine per provider added with AddRemoteProvider. Whenever a
+// provider emits a new payload, all remote providers are re-read with ReadRemoteConfig, so the merge order
+// and validator pipeline stay the same as a manual call.
+func (c *Config) WatchRemoteConfig(ctx context.Context) error {
+	c.RLock()
+	rps := append([]RemoteProvider{}, c.remoteProviders...)
+	c.RUnlock()
+
+	for _, rp := range rps {
+		ch, err := providers[rp.Provider].Watch(ctx, rp)
+		if err != nil {
+			return fmt.Errorf("couldn't watch remote config %q %q: %v", rp.Provider, rp.Path, err)
+		}
+		go c.watchRemote(ctx, ch)
+	}
+	return nil
+}
+
+func (c *Config) watchRemote(ctx context.Context, ch <-chan []byte) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := c.ReadRemoteConfig(); err != nil {
+				log.Printf("Error re-reading remote config, keeping existing config: %v", err)
+			}
+		}
+	}
+}
+
+// mergeRemoteProvider fetches rp's payload, decrypts it if a keyring was configured, decodes it, and
+// deep-merges it on top of mv.
+func (c *Config) mergeRemoteProvider(mv mxj.Map, rp RemoteProvider) (mxj.Map, error) {
+	body, err := providers[rp.Provider].Get(rp)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read remote config %q %q: %v", rp.Provider, rp.Path, err)
+	}
+	if rp.SecretKeyring != "" {
+		if body, err = decryptPayload(body, rp.SecretKeyring); err != nil {
+			return nil, fmt.Errorf("couldn't decrypt remote config %q %q: %v", rp.Provider, rp.Path, err)
+		}
+	}
+	m, err := decodeConfig(rp.Path, body)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse remote config %q %q: %v", rp.Provider, rp.Path, err)
+	}
+	return mxj.Map(mergeMaps(mv, m)), nil
+}
+
+// decryptPayload decrypts an AES-GCM encrypted payload using the raw key stored in keyringPath. The
+// payload is expected to be the GCM nonce followed by the ciphertext, as produced by sealing with the
+// same key.
+func decryptPayload(data []byte, keyringPath string) ([]byte, error) {
+	key, err := afero.ReadFile(Fs, keyringPath)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read secret keyring %q: %v", keyringPath, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AES key in %q: %v", keyringPath, err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted payload is shorter than the GCM nonce")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
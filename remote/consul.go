@@ -0,0 +1,52 @@
+package remote
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dparrish/go-autoconfig"
+)
+
+// consulProvider implements autoconfig.Provider against Consul's HTTP KV API.
+type consulProvider struct{}
+
+func (consulProvider) Get(rp autoconfig.RemoteProvider) ([]byte, error) {
+	endpoint := rp.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+
+	url := strings.TrimRight(endpoint, "/") + "/v1/kv/" + strings.TrimLeft(rp.Path, "/")
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't reach consul at %q: %v", rp.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("key %q not found in consul", rp.Path)
+	}
+
+	var entries []struct {
+		Value string `json:"Value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("couldn't decode consul response: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("key %q not found in consul", rp.Path)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(entries[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode consul value: %v", err)
+	}
+	return value, nil
+}
+
+func (p consulProvider) Watch(ctx context.Context, rp autoconfig.RemoteProvider) (<-chan []byte, error) {
+	return poll(ctx, func() ([]byte, error) { return p.Get(rp) }), nil
+}
@@ -0,0 +1,59 @@
+package remote
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dparrish/go-autoconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsulProviderGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/myapp/config", r.URL.Path)
+		w.Write([]byte(`[{"Value": "` + base64.StdEncoding.EncodeToString([]byte(`{"var1": "value1"}`)) + `"}]`))
+	}))
+	defer server.Close()
+
+	p := consulProvider{}
+	body, err := p.Get(autoconfig.RemoteProvider{Endpoint: server.URL, Path: "/myapp/config"})
+	require.Nil(t, err)
+	assert.Equal(t, `{"var1": "value1"}`, string(body))
+}
+
+func TestConsulProviderGetAddsSchemeIfMissing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"Value": "` + base64.StdEncoding.EncodeToString([]byte("ok")) + `"}]`))
+	}))
+	defer server.Close()
+
+	p := consulProvider{}
+	_, err := p.Get(autoconfig.RemoteProvider{Endpoint: strings.TrimPrefix(server.URL, "http://"), Path: "myapp/config"})
+	require.Nil(t, err)
+}
+
+func TestConsulProviderGetNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	p := consulProvider{}
+	_, err := p.Get(autoconfig.RemoteProvider{Endpoint: server.URL, Path: "/myapp/config"})
+	assert.NotNil(t, err)
+}
+
+func TestConsulProviderGetEmptyEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	p := consulProvider{}
+	_, err := p.Get(autoconfig.RemoteProvider{Endpoint: server.URL, Path: "/myapp/config"})
+	assert.NotNil(t, err)
+}
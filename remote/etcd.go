@@ -0,0 +1,54 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/dparrish/go-autoconfig"
+)
+
+// etcdProvider implements autoconfig.Provider against an etcd v3 cluster's HTTP gRPC-gateway API.
+type etcdProvider struct{}
+
+func (etcdProvider) Get(rp autoconfig.RemoteProvider) ([]byte, error) {
+	reqBody, err := json.Marshal(map[string]string{
+		"key": base64.StdEncoding.EncodeToString([]byte(rp.Path)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimRight(rp.Endpoint, "/") + "/v3/kv/range"
+	resp, err := http.Post(url, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't reach etcd at %q: %v", rp.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("couldn't decode etcd response: %v", err)
+	}
+	if len(result.Kvs) == 0 {
+		return nil, fmt.Errorf("key %q not found in etcd", rp.Path)
+	}
+
+	value, err := base64.StdEncoding.DecodeString(result.Kvs[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode etcd value: %v", err)
+	}
+	return value, nil
+}
+
+func (p etcdProvider) Watch(ctx context.Context, rp autoconfig.RemoteProvider) (<-chan []byte, error) {
+	return poll(ctx, func() ([]byte, error) { return p.Get(rp) }), nil
+}
@@ -0,0 +1,53 @@
+package remote
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dparrish/go-autoconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEtcdProviderGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/kv/range", r.URL.Path)
+
+		var req struct {
+			Key string `json:"key"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		require.Nil(t, json.Unmarshal(body, &req))
+		key, err := base64.StdEncoding.DecodeString(req.Key)
+		require.Nil(t, err)
+		assert.Equal(t, "/myapp/config", string(key))
+
+		resp := map[string]interface{}{
+			"kvs": []map[string]string{
+				{"value": base64.StdEncoding.EncodeToString([]byte(`{"var1": "value1"}`))},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	p := etcdProvider{}
+	got, err := p.Get(autoconfig.RemoteProvider{Endpoint: server.URL, Path: "/myapp/config"})
+	require.Nil(t, err)
+	assert.Equal(t, `{"var1": "value1"}`, string(got))
+}
+
+func TestEtcdProviderGetKeyNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"kvs": []map[string]string{}})
+	}))
+	defer server.Close()
+
+	p := etcdProvider{}
+	_, err := p.Get(autoconfig.RemoteProvider{Endpoint: server.URL, Path: "/myapp/config"})
+	assert.NotNil(t, err)
+}
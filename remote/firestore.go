@@ -0,0 +1,105 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/dparrish/go-autoconfig"
+)
+
+// defaultFirestoreEndpoint is used when RemoteProvider.Endpoint is empty.
+const defaultFirestoreEndpoint = "https://firestore.googleapis.com/v1"
+
+// firestoreProvider implements autoconfig.Provider against the Firestore REST API. RemoteProvider.Path
+// is the document's resource name, e.g. "projects/myproject/databases/(default)/documents/config/prod".
+// Authentication is expected to be supplied out of band via the FIRESTORE_TOKEN environment variable (a
+// valid OAuth2 bearer token); this package doesn't perform the OAuth2 dance itself.
+type firestoreProvider struct{}
+
+func (firestoreProvider) Get(rp autoconfig.RemoteProvider) ([]byte, error) {
+	endpoint := rp.Endpoint
+	if endpoint == "" {
+		endpoint = defaultFirestoreEndpoint
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(endpoint, "/")+"/"+strings.TrimLeft(rp.Path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("FIRESTORE_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't reach firestore at %q: %v", rp.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	var doc struct {
+		Fields map[string]json.RawMessage `json:"fields"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("couldn't decode firestore response: %v", err)
+	}
+
+	m := make(map[string]interface{}, len(doc.Fields))
+	for k, v := range doc.Fields {
+		m[k] = flattenFirestoreValue(v)
+	}
+	return json.Marshal(m)
+}
+
+func (p firestoreProvider) Watch(ctx context.Context, rp autoconfig.RemoteProvider) (<-chan []byte, error) {
+	return poll(ctx, func() ([]byte, error) { return p.Get(rp) }), nil
+}
+
+// flattenFirestoreValue unwraps a single Firestore typed value, e.g. {"stringValue": "x"} or
+// {"mapValue": {"fields": {...}}}, into the plain value (or nested map/slice) it represents.
+func flattenFirestoreValue(raw json.RawMessage) interface{} {
+	var wrapped map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil
+	}
+
+	if v, ok := wrapped["mapValue"]; ok {
+		var m struct {
+			Fields map[string]json.RawMessage `json:"fields"`
+		}
+		if err := json.Unmarshal(v, &m); err != nil {
+			return nil
+		}
+		out := make(map[string]interface{}, len(m.Fields))
+		for k, vv := range m.Fields {
+			out[k] = flattenFirestoreValue(vv)
+		}
+		return out
+	}
+
+	if v, ok := wrapped["arrayValue"]; ok {
+		var a struct {
+			Values []json.RawMessage `json:"values"`
+		}
+		if err := json.Unmarshal(v, &a); err != nil {
+			return nil
+		}
+		out := make([]interface{}, len(a.Values))
+		for i, vv := range a.Values {
+			out[i] = flattenFirestoreValue(vv)
+		}
+		return out
+	}
+
+	for _, v := range wrapped {
+		var plain interface{}
+		if err := json.Unmarshal(v, &plain); err != nil {
+			return nil
+		}
+		return plain
+	}
+	return nil
+}
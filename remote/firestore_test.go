@@ -0,0 +1,65 @@
+package remote
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dparrish/go-autoconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenFirestoreValue(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want interface{}
+	}{
+		{"string", `{"stringValue": "hello"}`, "hello"},
+		{"integer", `{"integerValue": "42"}`, "42"},
+		{"boolean", `{"booleanValue": true}`, true},
+		{
+			"nested map",
+			`{"mapValue": {"fields": {"inner": {"stringValue": "x"}}}}`,
+			map[string]interface{}{"inner": "x"},
+		},
+		{
+			"array",
+			`{"arrayValue": {"values": [{"stringValue": "a"}, {"stringValue": "b"}]}}`,
+			[]interface{}{"a", "b"},
+		},
+		{
+			"array of maps",
+			`{"arrayValue": {"values": [{"mapValue": {"fields": {"k": {"stringValue": "v"}}}}]}}`,
+			[]interface{}{map[string]interface{}{"k": "v"}},
+		},
+		{"invalid json", `not json`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, flattenFirestoreValue(json.RawMessage(tt.raw)))
+		})
+	}
+}
+
+func TestFirestoreProviderGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/projects/myproject/databases/(default)/documents/config/prod", r.URL.Path)
+		w.Write([]byte(`{"fields": {"var1": {"stringValue": "value1"}, "intval1": {"integerValue": "15"}}}`))
+	}))
+	defer server.Close()
+
+	p := firestoreProvider{}
+	body, err := p.Get(autoconfig.RemoteProvider{
+		Endpoint: server.URL,
+		Path:     "projects/myproject/databases/(default)/documents/config/prod",
+	})
+	require.Nil(t, err)
+
+	var got map[string]interface{}
+	require.Nil(t, json.Unmarshal(body, &got))
+	assert.Equal(t, "value1", got["var1"])
+	assert.Equal(t, "15", got["intval1"])
+}
@@ -0,0 +1,55 @@
+// Package remote implements autoconfig.Provider backends for etcd, Consul, and Firestore, so that a
+// Config can load and watch configuration stored in one of those systems instead of (or in addition to) a
+// local file. Importing the package for its side effects registers all three backends:
+//
+//	import _ "github.com/dparrish/go-autoconfig/remote"
+//
+// It lives in its own module so that the core autoconfig package doesn't pull in an HTTP client footprint
+// (or, for other backends, a full client SDK) for users who never touch remote configuration.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/dparrish/go-autoconfig"
+)
+
+func init() {
+	autoconfig.RegisterProvider("etcd", etcdProvider{})
+	autoconfig.RegisterProvider("consul", consulProvider{})
+	autoconfig.RegisterProvider("firestore", firestoreProvider{})
+}
+
+// pollInterval is how often poll re-fetches a provider to check for changes.
+const pollInterval = 10 * time.Second
+
+// poll starts a goroutine that calls get every pollInterval and emits the payload on the returned channel
+// whenever it differs from the last one seen. None of these backends expose a push-based watch over a
+// bare HTTP client, so polling is the simplest correct way to satisfy autoconfig.Provider's Watch method.
+// The goroutine exits as soon as ctx is done, even if nothing is currently receiving from the channel.
+func poll(ctx context.Context, get func() ([]byte, error)) <-chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		var last []byte
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollInterval):
+			}
+			body, err := get()
+			if err != nil || (last != nil && bytes.Equal(body, last)) {
+				continue
+			}
+			last = body
+			select {
+			case ch <- body:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,31 @@
+package remote
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPollStopsOnContextCancel checks that poll's background goroutine exits once its context is
+// cancelled, even when nothing is reading from the returned channel (which is the case once
+// WatchRemoteConfig's caller stops watching). Before poll selected on ctx.Done() around both the sleep
+// and the channel send, this goroutine ran forever.
+func TestPollStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	before := runtime.NumGoroutine()
+
+	poll(ctx, func() ([]byte, error) { return []byte("x"), nil })
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= before {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before, "poll's goroutine did not exit after ctx was cancelled")
+}
@@ -0,0 +1,107 @@
+package autoconfig
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider is an in-memory autoconfig.Provider used to test AddRemoteProvider/ReadRemoteConfig
+// without depending on the remote subpackage's real backends.
+type fakeProvider struct {
+	body []byte
+	ch   chan []byte
+}
+
+func (p *fakeProvider) Get(rp RemoteProvider) ([]byte, error) { return p.body, nil }
+func (p *fakeProvider) Watch(ctx context.Context, rp RemoteProvider) (<-chan []byte, error) {
+	return p.ch, nil
+}
+
+func TestReadRemoteConfig(t *testing.T) {
+	fake := &fakeProvider{body: []byte(`{"var1": "fromremote", "hash1": {"intval1": 42}}`)}
+	RegisterProvider("fake", fake)
+
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+	require.Nil(t, c.AddRemoteProvider("fake", "unused", "unused"))
+	require.Nil(t, c.ReadRemoteConfig())
+
+	// The remote payload replaces intval1 ...
+	assert.Equal(t, 42, c.GetInt("hash1.intval1"))
+	// ... but leaves the rest of hash1 untouched, since maps are deep-merged.
+	assert.Equal(t, "blah", c.Get("hash1.hash1var1"))
+	assert.Equal(t, "fromremote", c.Get("var1"))
+}
+
+func TestAddRemoteProviderUnknownBackend(t *testing.T) {
+	c := New("test.config")
+	assert.NotNil(t, c.AddRemoteProvider("nosuchbackend", "unused", "unused"))
+}
+
+func TestReadRemoteConfigWithKeyring(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.Nil(t, err)
+	afero.WriteFile(Fs, "test.keyring", key, 0600)
+	defer Fs.Remove("test.keyring")
+
+	plaintext := []byte(`{"var1": "fromencryptedremote"}`)
+	block, err := aes.NewCipher(key)
+	require.Nil(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.Nil(t, err)
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	require.Nil(t, err)
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	fake := &fakeProvider{body: ciphertext}
+	RegisterProvider("fakeencrypted", fake)
+
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+	require.Nil(t, c.AddRemoteProviderWithKeyring("fakeencrypted", "unused", "unused", "test.keyring"))
+	require.Nil(t, c.ReadRemoteConfig())
+	assert.Equal(t, "fromencryptedremote", c.Get("var1"))
+}
+
+func TestWatchRemoteConfig(t *testing.T) {
+	fake := &fakeProvider{
+		body: []byte(`{"var1": "initial"}`),
+		ch:   make(chan []byte, 1),
+	}
+	RegisterProvider("fakewatch", fake)
+
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+	require.Nil(t, c.AddRemoteProvider("fakewatch", "unused", "unused"))
+	require.Nil(t, c.ReadRemoteConfig())
+	assert.Equal(t, "initial", c.Get("var1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.Nil(t, c.WatchRemoteConfig(ctx))
+
+	fake.body = []byte(`{"var1": "updated"}`)
+	fake.ch <- fake.body
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if c.Get("var1") == "updated" {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, "updated", c.Get("var1"))
+}
@@ -0,0 +1,157 @@
+package autoconfig
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/clbanning/mxj"
+	"github.com/spf13/cast"
+)
+
+// GetBool looks up a configuration item in dotted path notation and returns it as a bool. It accepts the
+// usual truthy/falsy string forms ("true", "1", "t", "false", "0", ...), via github.com/spf13/cast.
+func (c *Config) GetBool(path string) bool {
+	i := c.GetRaw(path)
+	if i == nil {
+		return false
+	}
+	b, err := cast.ToBoolE(i)
+	if err != nil {
+		log.Printf("GetBool() Error in value %q, expected bool, got %v", path, i)
+		return false
+	}
+	return b
+}
+
+// GetDuration looks up a configuration item in dotted path notation and returns it as a time.Duration. Go
+// duration strings such as "500ms" or "2h45m" are parsed directly; a bare number (or numeric string) is
+// interpreted as a number of seconds (fractions included), matching the common YAML/JSON timeout convention.
+func (c *Config) GetDuration(path string) time.Duration {
+	i := c.GetRaw(path)
+	if i == nil {
+		return 0
+	}
+	if s, ok := i.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	seconds, err := cast.ToFloat64E(i)
+	if err != nil {
+		log.Printf("GetDuration() Error in value %q, expected duration, got %v", path, i)
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// GetTime looks up a configuration item in dotted path notation and returns it as a time.Time, parsed as
+// RFC3339 or one of a handful of other common layouts via github.com/spf13/cast.
+func (c *Config) GetTime(path string) time.Time {
+	i := c.GetRaw(path)
+	if i == nil {
+		return time.Time{}
+	}
+	t, err := cast.ToTimeE(i)
+	if err != nil {
+		log.Printf("GetTime() Error in value %q, expected time, got %v", path, i)
+		return time.Time{}
+	}
+	return t
+}
+
+// GetStringSlice looks up a configuration item in dotted path notation and returns it as a []string,
+// coercing a single scalar value into a one-element slice, the same way GetAll does.
+func (c *Config) GetStringSlice(path string) []string {
+	c.RLock()
+	defer c.RUnlock()
+	if v, ok := c.envValue(path); ok {
+		return strings.Split(v, c.envListSeparator)
+	}
+	values, err := c.mv.ValuesForPath(path)
+	if err != nil {
+		log.Printf("Error in ValuesForPath(%q): %v", path, err)
+	}
+
+	if len(values) == 0 {
+		values, err = c.defaults.ValuesForPath(path)
+		if err != nil {
+			log.Printf("Error in ValuesForPath(%q): %v", path, err)
+		}
+	}
+
+	if len(values) == 0 {
+		// Return an empty slice instead of nil so that client code doesn't have to check for nil.
+		return []string{}
+	}
+
+	r := make([]string, 0, len(values))
+	for _, v := range values {
+		s, err := cast.ToStringE(v)
+		if err != nil {
+			log.Printf("GetStringSlice() Error in value %q, expected string, got %v", path, v)
+			continue
+		}
+		r = append(r, s)
+	}
+	return r
+}
+
+// GetStringMap looks up a configuration item in dotted path notation and returns it as a
+// map[string]interface{}.
+func (c *Config) GetStringMap(path string) map[string]interface{} {
+	i := c.GetRaw(path)
+	if i == nil {
+		return map[string]interface{}{}
+	}
+	m, ok := toStringMap(i)
+	if !ok {
+		log.Printf("GetStringMap() Error in value %q, expected map, got %T", path, i)
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+// GetStringMapString looks up a configuration item in dotted path notation and returns it as a
+// map[string]string, coercing each value to a string.
+func (c *Config) GetStringMapString(path string) map[string]string {
+	i := c.GetRaw(path)
+	if i == nil {
+		return map[string]string{}
+	}
+	m, err := cast.ToStringMapStringE(i)
+	if err != nil {
+		log.Printf("GetStringMapString() Error in value %q, expected map[string]string, got %v", path, i)
+		return map[string]string{}
+	}
+	return m
+}
+
+// Sub returns a new Config rooted at path, so that Get("x") on the returned Config is equivalent to
+// Get(path+".x") on c for values from the configuration tree and defaults. The sub-config shares c's
+// validators and environment settings, but BindEnv bindings and AutomaticEnv names are matched against the
+// sub-config's own paths ("x"), not path+".x". It holds the part of the configuration tree under path as it
+// is at the time Sub is called; it does not track subsequent reloads of c.
+func (c *Config) Sub(path string) *Config {
+	c.RLock()
+	defer c.RUnlock()
+
+	sub := c.clone()
+	sub.mv = mxj.Map(subMap(c.mv, path))
+	sub.defaults = mxj.Map(subMap(c.defaults, path))
+	sub.loaded = c.loaded
+	return sub
+}
+
+// subMap returns the map rooted at path within m, or an empty map if path doesn't point at a map.
+func subMap(m mxj.Map, path string) map[string]interface{} {
+	values, err := m.ValuesForPath(path)
+	if err != nil || len(values) == 0 {
+		return map[string]interface{}{}
+	}
+	sub, ok := toStringMap(values[0])
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return sub
+}
@@ -0,0 +1,92 @@
+package autoconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const typedYAMLConfig = `
+flag1: true
+flag2: "false"
+timeout1: 500ms
+timeout2: 30
+started: "2020-01-02T15:04:05Z"
+list1: ["a", "b", "c"]
+list2: solo
+hash1:
+  hash1var1: blah
+  hash2:
+    hash2var1: ["foo", "bar"]
+stringmap:
+  key1: value1
+  key2: value2
+`
+
+func loadTypedConfig() *Config {
+	afero.WriteFile(Fs, "test.config", []byte(typedYAMLConfig), 0644)
+	c := New("test.config")
+	if err := c.Load(); err != nil {
+		return nil
+	}
+	return c
+}
+
+func TestGetBool(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	assert.True(t, c.GetBool("flag1"))
+	assert.False(t, c.GetBool("flag2"))
+	assert.False(t, c.GetBool("nosuchkey"))
+}
+
+func TestGetDuration(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	assert.Equal(t, 500*time.Millisecond, c.GetDuration("timeout1"))
+	assert.Equal(t, 30*time.Second, c.GetDuration("timeout2"))
+}
+
+func TestGetTime(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	assert.Equal(t, 2020, c.GetTime("started").Year())
+}
+
+func TestGetStringSlice(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	assert.Equal(t, []string{"a", "b", "c"}, c.GetStringSlice("list1"))
+	assert.Equal(t, []string{"solo"}, c.GetStringSlice("list2"))
+}
+
+func TestGetStringMap(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	m := c.GetStringMap("hash1")
+	assert.Equal(t, "blah", m["hash1var1"])
+}
+
+func TestGetStringMapString(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	assert.Equal(t, map[string]string{"key1": "value1", "key2": "value2"}, c.GetStringMapString("stringmap"))
+}
+
+func TestSub(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	sub := c.Sub("hash1")
+	assert.Equal(t, "blah", sub.Get("hash1var1"))
+	assert.Equal(t, []string{"foo", "bar"}, sub.GetAll("hash2.hash2var1"))
+}
+
+func TestSubMissingPath(t *testing.T) {
+	c := loadTypedConfig()
+	require.NotNil(t, c)
+	sub := c.Sub("nosuchkey")
+	assert.Equal(t, "", sub.Get("anything"))
+}
@@ -0,0 +1,95 @@
+package autoconfig
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Unmarshal decodes the current configuration tree into out, which must be a pointer to a struct (or map).
+// Fields are matched by name, case-insensitively, unless overridden with a `config:"name"` struct tag.
+// Values are weakly typed (e.g. a config string "5" decodes into an int field), and time.Duration and
+// time.Time fields are parsed from strings (Go duration syntax such as "500ms", and RFC3339
+// respectively); a []byte field is decoded from a hex-encoded string.
+func (c *Config) Unmarshal(out interface{}) error {
+	c.RLock()
+	m := map[string]interface{}(c.mv)
+	c.RUnlock()
+	return decodeStruct(m, out)
+}
+
+// UnmarshalKey is like Unmarshal, but decodes only the map rooted at path (in the same dotted path
+// notation as Get) instead of the whole configuration tree.
+func (c *Config) UnmarshalKey(path string, out interface{}) error {
+	c.RLock()
+	values, err := c.mv.ValuesForPath(path)
+	c.RUnlock()
+	if err != nil {
+		return fmt.Errorf("error in ValuesForPath(%q): %v", path, err)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("%q not found in the configuration", path)
+	}
+	m, ok := toStringMap(values[0])
+	if !ok {
+		return fmt.Errorf("%q is not a map in the configuration", path)
+	}
+	return decodeStruct(m, out)
+}
+
+// decodeStruct decodes m into out using mapstructure, with weak type conversion and decode hooks for
+// time.Duration, time.Time, and hex-encoded []byte so that callers don't have to hand-roll conversions
+// for the common cases.
+func decodeStruct(m map[string]interface{}, out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Metadata:         nil,
+		Result:           out,
+		TagName:          "config",
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToTimeHookFunc(time.RFC3339),
+			stringToHexByteSliceHookFunc,
+		),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't build config decoder: %v", err)
+	}
+	if err := decoder.Decode(m); err != nil {
+		return fmt.Errorf("couldn't decode configuration: %v", err)
+	}
+	return nil
+}
+
+// stringToHexByteSliceHookFunc decodes a hex-encoded string into a []byte field, e.g. for a config value
+// holding a binary key or token.
+func stringToHexByteSliceHookFunc(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+	if f.Kind() != reflect.String {
+		return data, nil
+	}
+	if t != reflect.TypeOf([]byte(nil)) {
+		return data, nil
+	}
+	return hex.DecodeString(data.(string))
+}
+
+// toStringMap converts v into a map[string]interface{}, handling both the map[string]interface{} that
+// mxj and the decoders produce and the map[interface{}]interface{} that ValuesForPath can also return for
+// nested values. It reports false if v isn't a map at all.
+func toStringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, vv := range m {
+			out[fmt.Sprintf("%v", k)] = vv
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
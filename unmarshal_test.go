@@ -0,0 +1,99 @@
+package autoconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unmarshalConfig = `
+var1: value1
+hash1:
+  hash1var1: blah
+  intval1: 15
+  timeout: 500ms
+  started: "2020-01-02T15:04:05Z"
+  secret: "deadbeef"
+`
+
+type unmarshalTarget struct {
+	Var1  string `config:"var1"`
+	Hash1 struct {
+		Hash1Var1 string        `config:"hash1var1"`
+		IntVal1   int           `config:"intval1"`
+		Timeout   time.Duration `config:"timeout"`
+		Started   time.Time     `config:"started"`
+		Secret    []byte        `config:"secret"`
+	} `config:"hash1"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(unmarshalConfig), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+
+	var out unmarshalTarget
+	require.Nil(t, c.Unmarshal(&out))
+	assert.Equal(t, "value1", out.Var1)
+	assert.Equal(t, "blah", out.Hash1.Hash1Var1)
+	assert.Equal(t, 15, out.Hash1.IntVal1)
+	assert.Equal(t, 500*time.Millisecond, out.Hash1.Timeout)
+	assert.Equal(t, 2020, out.Hash1.Started.Year())
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, out.Hash1.Secret)
+}
+
+func TestUnmarshalKey(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(unmarshalConfig), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+
+	var out struct {
+		Hash1Var1 string `config:"hash1var1"`
+		IntVal1   int    `config:"intval1"`
+	}
+	require.Nil(t, c.UnmarshalKey("hash1", &out))
+	assert.Equal(t, "blah", out.Hash1Var1)
+	assert.Equal(t, 15, out.IntVal1)
+}
+
+func TestUnmarshalKeyNotFound(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(unmarshalConfig), 0644)
+	c := New("test.config")
+	require.Nil(t, c.Load())
+
+	var out struct{}
+	assert.NotNil(t, c.UnmarshalKey("nosuchkey", &out))
+}
+
+func TestOnChange(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+
+	var seen []string
+	c.OnChange(func(c *Config) {
+		seen = append(seen, c.Get("var1"))
+	})
+
+	require.Nil(t, c.Load())
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[1]), 0644)
+	require.Nil(t, c.read())
+
+	assert.Equal(t, []string{"value1", "value2"}, seen)
+}
+
+func TestOnChangeNotCalledOnValidationFailure(t *testing.T) {
+	afero.WriteFile(Fs, "test.config", []byte(JSONConfigs[0]), 0644)
+	c := New("test.config")
+	c.AddValidator(func(old, new *Config) error {
+		return assert.AnError
+	})
+
+	calls := 0
+	c.OnChange(func(c *Config) { calls++ })
+
+	assert.NotNil(t, c.Load())
+	assert.Equal(t, 0, calls)
+}